@@ -3,31 +3,71 @@ package dbtx
 import (
 	"context"
 	"database/sql"
-	"fmt"
 )
 
 // TxFuncWithContext adalah tipe callback transaksi yang menerima ctx dan tx
 type TxFuncWithContext func(ctx context.Context, tx *sql.Tx) error
 
-// WithTxContext menjalankan fn di dalam transaksi yang didukung context
-// Transaksi akan bergantung pada ctx -- dibatalkan jika ctx dibatalkan
-func WithTxContext(ctx context.Context, db *sql.DB, fn TxFuncWithContext) (err error) {
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("dbtx: begin tx failed: %w", err)
+// txState menyimpan tx yang sedang aktif di dalam ctx, beserta kedalaman
+// nesting-nya supaya WithTxContext bisa memberi nama SAVEPOINT yang unik.
+type txState struct {
+	tx    *sql.Tx
+	depth int
+
+	commitHooks   []func()
+	rollbackHooks []func(error)
+}
+
+type txStateKey struct{}
+
+// WithTxContext menjalankan fn di dalam transaksi yang didukung context.
+// Transaksi akan bergantung pada ctx -- dibatalkan jika ctx dibatalkan.
+//
+// Jika ctx sudah membawa *sql.Tx aktif (mis. karena dipanggil dari dalam
+// WithTxContext lain), fn akan ikut berjalan di tx yang sama menggunakan
+// SAVEPOINT/ROLLBACK TO SAVEPOINT sebagai batas nested, bukan membuka tx baru.
+//
+// Ini adalah sugar untuk WithTxContextOpts tanpa Option, supaya begin/commit/
+// rollback/hook dan instrumentasi Tracer/Logger cuma dijaga di satu tempat.
+func WithTxContext(ctx context.Context, db ForTx, fn TxFuncWithContext) error {
+	return WithTxContextOpts(ctx, db, fn)
+}
+
+// From mengambil *sql.Tx aktif dari ctx, jika ada. ok bernilai false kalau
+// ctx tidak sedang berada di dalam WithTxContext.
+func From(ctx context.Context) (*sql.Tx, bool) {
+	state, ok := ctx.Value(txStateKey{}).(*txState)
+	if !ok {
+		return nil, false
 	}
+	return state.tx, true
+}
 
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			_ = tx.Rollback()
-		} else if commitErr := tx.Commit(); commitErr != nil {
-			err = fmt.Errorf("dbtx: commit failed: %w", commitErr)
-		}
-	}()
-
-	err = fn(ctx, tx)
-	return
+// InTransaction melaporkan apakah ctx sedang membawa transaksi aktif.
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txStateKey{}).(*txState)
+	return ok
+}
+
+// Runner menyamaratakan *sql.DB dan *sql.Tx sehingga kode repository bisa
+// ditulis sekali untuk dua mode: langsung ke pool, atau di dalam transaksi.
+type Runner interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+var (
+	_ Runner = (*sql.DB)(nil)
+	_ Runner = (*sql.Tx)(nil)
+)
+
+// Exec memilih tx dari ctx jika ada (hasil WithTxContext), lalu jatuh balik
+// ke runner apa adanya jika ctx tidak sedang dalam transaksi. Ini memungkinkan
+// kode repository memanggil Exec tanpa perlu tahu apakah sedang ditransaksikan.
+func Exec(ctx context.Context, runner Runner, query string, args ...any) (sql.Result, error) {
+	if tx, ok := From(ctx); ok {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	return runner.ExecContext(ctx, query, args...)
 }
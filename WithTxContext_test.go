@@ -0,0 +1,128 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestFrom_OutsideTransaction(t *testing.T) {
+	if _, ok := From(context.Background()); ok {
+		t.Fatalf("expected ok=false outside WithTxContext")
+	}
+	if InTransaction(context.Background()) {
+		t.Fatalf("expected InTransaction=false outside WithTxContext")
+	}
+}
+
+func TestWithTxContext_CommitsAndExposesTxInContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err = WithTxContext(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		if !InTransaction(ctx) {
+			t.Fatalf("expected InTransaction=true inside WithTxContext")
+		}
+		gotTx, ok := From(ctx)
+		if !ok || gotTx != tx {
+			t.Fatalf("expected From(ctx) to return the same *sql.Tx")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWithTxContext_NestedCallJoinsViaSavepoint(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	forTx := NewForTx(db)
+	err = WithTxContext(context.Background(), forTx, func(ctx context.Context, outerTx *sql.Tx) error {
+		return WithTxContext(ctx, forTx, func(ctx context.Context, innerTx *sql.Tx) error {
+			if innerTx != outerTx {
+				t.Fatalf("expected nested call to reuse the outer *sql.Tx")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWithTxContext_NestedErrorRollsBackToSavepointOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	forTx := NewForTx(db)
+	err = WithTxContext(context.Background(), forTx, func(ctx context.Context, tx *sql.Tx) error {
+		nestedErr := WithTxContext(ctx, forTx, func(ctx context.Context, tx *sql.Tx) error {
+			return errRetryable
+		})
+		if nestedErr == nil {
+			t.Fatalf("expected nested WithTxContext to return an error")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: expected outer commit to succeed despite nested rollback, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestExec_PicksTxFromContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE widgets").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = WithTxContext(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		_, execErr := Exec(ctx, db, "UPDATE widgets SET name = ?", "widget")
+		return execErr
+	})
+	if err != nil {
+		t.Fatalf("Exec: unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ForTx membungkus sebuah *sql.DB yang didedikasikan sebagai connection pool
+// untuk transaksi. Tipe ini sengaja terpisah dari *sql.DB biasa: jika pool
+// yang sama dipakai baik untuk WithTxContext maupun untuk query ambient
+// (db.QueryContext langsung di luar transaksi), pool berukuran kecil bisa
+// deadlock selamanya -- koneksi yang dipegang tx menunggu koneksi lain yang
+// tidak pernah dilepas karena query ambient menunggu slot yang sama.
+//
+// Konstruksi ForTx lewat NewForTx secara eksplisit menandai "*sql.DB ini
+// khusus untuk transaksi", sehingga pemanggil tidak bisa meneruskan *sql.DB
+// ambient begitu saja tanpa sadar.
+type ForTx struct {
+	db *sql.DB
+}
+
+// NewForTx membungkus db sebagai pool khusus transaksi.
+func NewForTx(db *sql.DB) ForTx {
+	return ForTx{db: db}
+}
+
+// txBeginner adalah permukaan minimal yang dibutuhkan WithTxContext untuk
+// membuka transaksi. ForTx mengimplementasikannya tanpa mengekspos
+// ExecContext/QueryContext, jadi kode yang hanya punya ForTx tidak bisa
+// dipakai untuk query ambient di luar transaksi.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+func (f ForTx) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return f.db.BeginTx(ctx, opts)
+}
+
+var _ txBeginner = ForTx{}
@@ -0,0 +1,75 @@
+package dbtx
+
+import "context"
+
+// OnCommit mendaftarkan fn untuk dijalankan setelah transaksi yang sedang
+// aktif di ctx berhasil commit -- berguna untuk efek samping yang baru boleh
+// terjadi setelah nasib transaksi benar-benar final (invalidasi cache,
+// publish ke outbox, emit metric). Tidak melakukan apa-apa jika ctx tidak
+// sedang berada di dalam WithTxContext.
+//
+// Hook dijalankan dalam urutan LIFO (hook yang didaftarkan terakhir jalan
+// duluan) dan masing-masing di-recover secara individual, jadi satu hook
+// yang panic tidak menghalangi hook lain untuk tetap jalan.
+func OnCommit(ctx context.Context, fn func()) {
+	state, ok := ctx.Value(txStateKey{}).(*txState)
+	if !ok {
+		return
+	}
+	state.commitHooks = append(state.commitHooks, fn)
+}
+
+// OnRollback mendaftarkan fn untuk dijalankan setelah transaksi yang sedang
+// aktif di ctx dibatalkan, baik karena fn mengembalikan error, karena panic,
+// maupun karena commit itu sendiri gagal. err yang diteruskan adalah
+// penyebab rollback tersebut. Tidak melakukan apa-apa jika ctx tidak sedang
+// berada di dalam WithTxContext.
+func OnRollback(ctx context.Context, fn func(err error)) {
+	state, ok := ctx.Value(txStateKey{}).(*txState)
+	if !ok {
+		return
+	}
+	state.rollbackHooks = append(state.rollbackHooks, fn)
+}
+
+// runCommitHooks menjalankan commit hooks dalam urutan LIFO, masing-masing
+// di-recover sendiri-sendiri agar satu hook yang panic tidak memblokir hook
+// lainnya.
+func runCommitHooks(state *txState) {
+	for i := len(state.commitHooks) - 1; i >= 0; i-- {
+		runHookSafely(state.commitHooks[i])
+	}
+}
+
+// runRollbackHooks menjalankan rollback hooks dalam urutan LIFO dengan
+// penyebab rollback err, masing-masing di-recover sendiri-sendiri.
+func runRollbackHooks(state *txState, err error) {
+	for i := len(state.rollbackHooks) - 1; i >= 0; i-- {
+		hook := state.rollbackHooks[i]
+		runHookSafely(func() { hook(err) })
+	}
+}
+
+// runHookSafely menjalankan satu hook dan meredam panic-nya supaya hook lain
+// tetap bisa jalan.
+func runHookSafely(fn func()) {
+	defer func() { _ = recover() }()
+	fn()
+}
+
+// discardNestedHooks dipanggil saat sebuah batas SAVEPOINT rollback ke
+// savepoint-nya sendiri (bukan seluruh transaksi). commitHooks dan
+// rollbackHooks yang didaftarkan selama batas nested itu (yaitu yang ada di
+// atas commitFrom/rollbackFrom) bukan lagi bagian dari nasib transaksi luar:
+// commit hook-nya dibuang karena kerjanya sudah dibatalkan, dan rollback
+// hook-nya dijalankan sekarang juga dengan err penyebabnya -- supaya
+// keduanya tidak salah tereksekusi saat transaksi luar akhirnya commit.
+func discardNestedHooks(state *txState, commitFrom, rollbackFrom int, err error) {
+	nestedRollbackHooks := state.rollbackHooks[rollbackFrom:]
+	for i := len(nestedRollbackHooks) - 1; i >= 0; i-- {
+		hook := nestedRollbackHooks[i]
+		runHookSafely(func() { hook(err) })
+	}
+	state.rollbackHooks = state.rollbackHooks[:rollbackFrom]
+	state.commitHooks = state.commitHooks[:commitFrom]
+}
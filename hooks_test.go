@@ -0,0 +1,129 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOnCommit_RunsAfterCommitInLIFOOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	var order []int
+	err = WithTxContext(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		OnCommit(ctx, func() { order = append(order, 1) })
+		OnCommit(ctx, func() { order = append(order, 2) })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: unexpected error: %v", err)
+	}
+	if len(order) != 2 || order[0] != 2 || order[1] != 1 {
+		t.Fatalf("expected LIFO order [2 1], got %v", order)
+	}
+}
+
+func TestOnRollback_RunsWithCauseAndNotOnCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	committed := false
+	var gotErr error
+	err = WithTxContext(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		OnCommit(ctx, func() { committed = true })
+		OnRollback(ctx, func(err error) { gotErr = err })
+		return errRetryable
+	})
+	if !errors.Is(err, errRetryable) {
+		t.Fatalf("expected errRetryable, got %v", err)
+	}
+	if committed {
+		t.Fatalf("commit hook must not run on rollback")
+	}
+	if !errors.Is(gotErr, errRetryable) {
+		t.Fatalf("expected rollback hook to receive errRetryable, got %v", gotErr)
+	}
+}
+
+func TestHooks_NestedRollbackDoesNotLeakIntoOuterCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	forTx := NewForTx(db)
+	nestedCommitFired := false
+	nestedRollbackFired := false
+	outerCommitFired := false
+
+	err = WithTxContext(context.Background(), forTx, func(ctx context.Context, tx *sql.Tx) error {
+		_ = WithTxContext(ctx, forTx, func(ctx context.Context, tx *sql.Tx) error {
+			OnCommit(ctx, func() { nestedCommitFired = true })
+			OnRollback(ctx, func(error) { nestedRollbackFired = true })
+			return errRetryable
+		})
+		OnCommit(ctx, func() { outerCommitFired = true })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: unexpected error: %v", err)
+	}
+	if nestedCommitFired {
+		t.Fatalf("commit hook registered in a rolled-back nested scope must not fire at outer commit")
+	}
+	if !nestedRollbackFired {
+		t.Fatalf("rollback hook registered in a rolled-back nested scope must fire immediately")
+	}
+	if !outerCommitFired {
+		t.Fatalf("outer commit hook should still fire")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestHooks_PanickingHookDoesNotBlockOthers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	secondRan := false
+	err = WithTxContext(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		OnCommit(ctx, func() { secondRan = true })
+		OnCommit(ctx, func() { panic("boom") })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: unexpected error: %v", err)
+	}
+	if !secondRan {
+		t.Fatalf("a panicking hook must not prevent other hooks from running")
+	}
+}
@@ -0,0 +1,202 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// txConfig menampung opsi yang dikumpulkan dari Option sebelum transaksi
+// dibuka.
+type txConfig struct {
+	txOpts        sql.TxOptions
+	ignoredErrors []error
+	tracer        Tracer
+	logger        Logger
+	slowThreshold time.Duration
+}
+
+// Option mengubah konfigurasi transaksi sebelum BeginTx dipanggil, mengikuti
+// gaya functional-options.
+type Option func(*txConfig)
+
+// WithIsolation menetapkan isolation level transaksi.
+func WithIsolation(level sql.IsolationLevel) Option {
+	return func(c *txConfig) {
+		c.txOpts.Isolation = level
+	}
+}
+
+// WithReadOnly menandai transaksi sebagai read-only, memberi hint ke driver
+// bahwa tidak akan ada perubahan data di dalamnya.
+func WithReadOnly() Option {
+	return func(c *txConfig) {
+		c.txOpts.ReadOnly = true
+	}
+}
+
+// WithIgnoredErrors mendaftarkan sentinel error (mis. ErrNotFound) yang tetap
+// dikembalikan ke pemanggil apa adanya, tapi tidak memicu rollback -- tx
+// tetap commit. Berguna untuk transaksi yang isinya query saja, di mana
+// "tidak ada baris" adalah hasil bisnis yang wajar, bukan kegagalan.
+func WithIgnoredErrors(errs ...error) Option {
+	return func(c *txConfig) {
+		c.ignoredErrors = append(c.ignoredErrors, errs...)
+	}
+}
+
+// WithTracer melengkapi transaksi dengan Tracer, dipanggil di awal (StartTx)
+// dan di akhir (EndTx) siklus hidup transaksi.
+func WithTracer(t Tracer) Option {
+	return func(c *txConfig) {
+		c.tracer = t
+	}
+}
+
+// WithLogger melengkapi transaksi dengan Logger yang dipanggil jika durasi
+// transaksi melebihi threshold.
+func WithLogger(l Logger, threshold time.Duration) Option {
+	return func(c *txConfig) {
+		c.logger = l
+		c.slowThreshold = threshold
+	}
+}
+
+func isIgnoredError(err error, ignored []error) bool {
+	for _, target := range ignored {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTxContextOpts adalah versi WithTxContext yang menerima Option untuk
+// mengatur isolation level, read-only, dan sentinel error yang tidak boleh
+// memicu rollback. Sama seperti WithTxContext, jika ctx sudah membawa tx
+// aktif, fn bergabung ke tx tersebut lewat SAVEPOINT dan opsi TxOptions
+// diabaikan (isolation level tidak bisa diubah di tengah transaksi) --
+// namun WithIgnoredErrors tetap berlaku untuk batas nested ini.
+func WithTxContextOpts(ctx context.Context, db ForTx, fn TxFuncWithContext, opts ...Option) (err error) {
+	cfg := &txConfig{tracer: noopTracer{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if state, ok := ctx.Value(txStateKey{}).(*txState); ok {
+		return withSavepointOpts(ctx, state, fn, cfg)
+	}
+
+	ctx = cfg.tracer.StartTx(ctx, cfg.txOpts)
+	started := time.Now()
+
+	tx, err := db.BeginTx(ctx, &cfg.txOpts)
+	if err != nil {
+		err = fmt.Errorf("dbtx: begin tx failed: %w", err)
+		cfg.tracer.EndTx(ctx, err)
+		return err
+	}
+
+	state := &txState{tx: tx}
+	ctx = context.WithValue(ctx, txStateKey{}, state)
+
+	defer func() {
+		// tracerErr reflects apakah transaksi sungguh-sungguh rollback, bukan
+		// err mentah -- sebuah WithIgnoredErrors sentinel tetap commit, jadi
+		// tidak boleh tercatat sebagai kegagalan di Tracer/span.
+		var tracerErr error
+
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panicErr := fmt.Errorf("dbtx: panic: %v", p)
+			runRollbackHooks(state, panicErr)
+			tracerErr = panicErr
+			cfg.tracer.EndTx(ctx, tracerErr)
+			logSlow(ctx, cfg, started)
+			panic(p)
+		} else if err != nil && !isIgnoredError(err, cfg.ignoredErrors) {
+			_ = tx.Rollback()
+			runRollbackHooks(state, err)
+			tracerErr = err
+		} else if commitErr := tx.Commit(); commitErr != nil {
+			err = fmt.Errorf("dbtx: commit failed: %w", commitErr)
+			runRollbackHooks(state, err)
+			tracerErr = err
+		} else {
+			runCommitHooks(state)
+		}
+		cfg.tracer.EndTx(ctx, tracerErr)
+		logSlow(ctx, cfg, started)
+	}()
+
+	err = fn(ctx, tx)
+	return
+}
+
+// logSlow memanggil cfg.logger jika durasi transaksi sejak started melebihi
+// cfg.slowThreshold.
+func logSlow(ctx context.Context, cfg *txConfig, started time.Time) {
+	if cfg.logger == nil || cfg.slowThreshold <= 0 {
+		return
+	}
+	if d := time.Since(started); d > cfg.slowThreshold {
+		cfg.logger.SlowTransaction(ctx, d)
+	}
+}
+
+// withSavepointOpts menjalankan fn di tx yang sudah ada dalam ctx, dibatasi
+// oleh SAVEPOINT agar error di fn hanya membatalkan perubahan nested ini,
+// bukan seluruh transaksi induk, sambil menghormati ignoredErrors milik
+// batas nested ini.
+func withSavepointOpts(ctx context.Context, state *txState, fn TxFuncWithContext, cfg *txConfig) (err error) {
+	state.depth++
+	name := fmt.Sprintf("dbtx_sp_%d", state.depth)
+	commitFrom := len(state.commitHooks)
+	rollbackFrom := len(state.rollbackHooks)
+
+	if _, execErr := state.tx.ExecContext(ctx, "SAVEPOINT "+name); execErr != nil {
+		state.depth--
+		return fmt.Errorf("dbtx: create savepoint failed: %w", execErr)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			discardNestedHooks(state, commitFrom, rollbackFrom, fmt.Errorf("dbtx: panic: %v", p))
+			state.depth--
+			panic(p)
+		} else if err != nil && !isIgnoredError(err, cfg.ignoredErrors) {
+			_, _ = state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			discardNestedHooks(state, commitFrom, rollbackFrom, err)
+			state.depth--
+		} else if _, relErr := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); relErr != nil {
+			err = fmt.Errorf("dbtx: release savepoint failed: %w", relErr)
+			discardNestedHooks(state, commitFrom, rollbackFrom, err)
+			state.depth--
+		} else {
+			state.depth--
+		}
+	}()
+
+	err = fn(ctx, state.tx)
+	return
+}
+
+// ReadOnly adalah sugar untuk WithTxContextOpts(ctx, db, fn, WithReadOnly()).
+func ReadOnly(ctx context.Context, db ForTx, fn TxFuncWithContext) error {
+	return WithTxContextOpts(ctx, db, fn, WithReadOnly())
+}
+
+// Serializable adalah sugar untuk WithTxContextOpts dengan isolation level
+// sql.LevelSerializable.
+func Serializable(ctx context.Context, db ForTx, fn TxFuncWithContext) error {
+	return WithTxContextOpts(ctx, db, fn, WithIsolation(sql.LevelSerializable))
+}
+
+// RepeatableRead adalah sugar untuk WithTxContextOpts dengan isolation level
+// sql.LevelRepeatableRead.
+func RepeatableRead(ctx context.Context, db ForTx, fn TxFuncWithContext) error {
+	return WithTxContextOpts(ctx, db, fn, WithIsolation(sql.LevelRepeatableRead))
+}
@@ -0,0 +1,79 @@
+// Package otel menyediakan adapter dbtx.Tracer yang mengemit satu span
+// OpenTelemetry per percobaan transaksi.
+package otel
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gogaruda/dbtx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer mengimplementasikan dbtx.Tracer dengan mengemit span ber-nama
+// "dbtx.tx" lewat tracer OpenTelemetry yang diberikan. Span membawa atribut
+// read-only, isolation level, dan hasil akhir (commit/rollback); durasinya
+// hanya tercatat lewat waktu mulai dan selesai span, tanpa atribut durasi
+// tersendiri. Nomor percobaan tidak dicatat di span "dbtx.tx"; atribut
+// dbtx.attempt hanya ada di span "dbtx.retry" yang dibuat OnRetry.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New membuat Tracer yang mengemit span lewat tr. Kosongkan tr untuk memakai
+// tracer global dari otel.Tracer("dbtx").
+func New(tr trace.Tracer) *Tracer {
+	if tr == nil {
+		tr = otel.Tracer("dbtx")
+	}
+	return &Tracer{tracer: tr}
+}
+
+type spanKey struct{}
+
+// StartTx membuka span baru untuk percobaan transaksi ini. WithTxRetry
+// memanggil WithTxContextOpts ulang dari awal untuk tiap percobaan, jadi
+// StartTx/EndTx sudah otomatis menghasilkan satu span per percobaan tanpa
+// perlu koordinasi tambahan dengan OnRetry.
+func (t *Tracer) StartTx(ctx context.Context, opts sql.TxOptions) context.Context {
+	ctx, span := t.tracer.Start(ctx, "dbtx.tx")
+	span.SetAttributes(
+		attribute.Bool("dbtx.read_only", opts.ReadOnly),
+		attribute.String("dbtx.isolation", opts.Isolation.String()),
+	)
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// EndTx menutup span yang dibuka StartTx, menandai status error jika err != nil.
+func (t *Tracer) EndTx(ctx context.Context, err error) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.Bool("dbtx.committed", false))
+	} else {
+		span.SetAttributes(attribute.Bool("dbtx.committed", true))
+	}
+	span.End()
+}
+
+// OnRetry dipanggil oleh WithTxRetry setiap kali sebuah percobaan gagal dan
+// akan diulang. ctx di sini adalah ctx milik WithTxRetry, bukan ctx
+// ber-span yang dibuat StartTx untuk percobaan yang baru saja berakhir
+// (span tersebut sudah ditutup EndTx), jadi OnRetry mencatatnya sebagai span
+// singkat tersendiri alih-alih event di span yang sudah selesai.
+func (t *Tracer) OnRetry(ctx context.Context, attempt int, err error) {
+	_, span := t.tracer.Start(ctx, "dbtx.retry")
+	span.SetAttributes(
+		attribute.Int("dbtx.attempt", attempt),
+		attribute.String("dbtx.error", err.Error()),
+	)
+	span.End()
+}
+
+var _ dbtx.Tracer = (*Tracer)(nil)
@@ -0,0 +1,137 @@
+package dbtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// ErrNestedRetry dikembalikan oleh WithTxRetry jika ctx yang diberikan sudah
+// membawa transaksi aktif. Retry yang sebenarnya membutuhkan BeginTx baru di
+// setiap percobaan -- kalau ctx sudah di dalam tx, "retry" hanya akan
+// menjadi SAVEPOINT di tx yang sama, yang tidak berguna untuk kasus seperti
+// serialization failure CockroachDB "40001" karena tx tersebut sudah
+// dianggap gagal oleh server dan savepoint di atasnya akan gagal juga.
+var ErrNestedRetry = errors.New("dbtx: WithTxRetry cannot retry inside an existing transaction")
+
+// RetryPolicy mengatur berapa kali dan seberapa lama WithTxRetry mencoba ulang
+// sebuah transaksi ketika driver melaporkan error yang bersifat sementara
+// (misalnya serialization failure atau deadlock).
+type RetryPolicy struct {
+	// MaxAttempts adalah jumlah maksimum percobaan, termasuk percobaan pertama.
+	// Nilai <= 1 berarti tidak ada retry sama sekali.
+	MaxAttempts int
+
+	// BaseBackoff adalah jeda sebelum percobaan kedua.
+	BaseBackoff time.Duration
+
+	// MaxBackoff adalah batas atas jeda antar percobaan, backoff naik secara
+	// eksponensial dari BaseBackoff sampai mentok di sini.
+	MaxBackoff time.Duration
+
+	// Jitter, jika true, menambahkan variasi acak pada jeda agar beberapa
+	// goroutine yang retry bersamaan tidak saling bertabrakan lagi.
+	Jitter bool
+
+	// IsRetryable menentukan apakah sebuah error layak dicoba ulang. Wajib diisi
+	// pemanggil karena kode error retriable berbeda-beda antar driver
+	// (mis. CockroachDB "40001", MySQL deadlock 1213, Postgres "40P01").
+	IsRetryable func(error) bool
+
+	// Tracer, jika diisi, menerima StartTx/EndTx untuk tiap percobaan dan
+	// OnRetry setiap kali sebuah percobaan gagal dan akan diulang -- berguna
+	// untuk memantau hot spot dan retry storm.
+	Tracer Tracer
+}
+
+// RetryError membungkus error terakhir dari WithTxRetry beserta jumlah
+// percobaan yang sudah dilakukan, sehingga pemanggil bisa membedakan antara
+// retry yang habis dengan kegagalan yang memang tidak retriable.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("dbtx: failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// WithTxRetry menjalankan fn di dalam transaksi seperti WithTxContextOpts,
+// tapi mengulang dari awal (BeginTx baru) jika error yang dikembalikan
+// dianggap retriable oleh policy.IsRetryable. Setiap percobaan mendapat
+// *sql.Tx baru; panic tetap memicu rollback dan di-re-panic tanpa memakan
+// jatah retry. opts diteruskan ke setiap percobaan, jadi retry bisa
+// dikombinasikan dengan isolation level lewat WithIsolation/Serializable,
+// misalnya untuk menangani serialization failure CockroachDB "40001".
+func WithTxRetry(ctx context.Context, db ForTx, policy RetryPolicy, fn TxFuncWithContext, opts ...Option) error {
+	if InTransaction(ctx) {
+		return ErrNestedRetry
+	}
+
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	if policy.Tracer != nil {
+		opts = append(opts, WithTracer(policy.Tracer))
+	}
+
+	var lastErr error
+	attempt := 1
+	for ; attempt <= maxAttempts; attempt++ {
+		err := WithTxContextOpts(ctx, db, fn, opts...)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		if attempt == maxAttempts || policy.IsRetryable == nil || !policy.IsRetryable(err) {
+			break
+		}
+
+		if policy.Tracer != nil {
+			policy.Tracer.OnRetry(ctx, attempt, err)
+		}
+
+		if sleepErr := sleepBackoff(ctx, policy, attempt); sleepErr != nil {
+			lastErr = sleepErr
+			break
+		}
+	}
+
+	return &RetryError{Attempts: attempt, Err: lastErr}
+}
+
+// sleepBackoff menunggu selama backoff eksponensial (dibatasi MaxBackoff,
+// dengan jitter opsional) sebelum percobaan berikutnya, dan berhenti lebih
+// awal jika ctx dibatalkan.
+func sleepBackoff(ctx context.Context, policy RetryPolicy, attempt int) error {
+	backoff := policy.BaseBackoff << uint(attempt-1)
+	if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	if backoff < 0 {
+		backoff = policy.MaxBackoff
+	}
+
+	if policy.Jitter && backoff > 0 {
+		backoff = time.Duration(rand.Int63n(int64(backoff)))
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("dbtx: retry aborted: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
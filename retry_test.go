@@ -0,0 +1,106 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var errRetryable = errors.New("serialization failure")
+
+func TestWithTxRetry_SucceedsAfterRetryableError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	attempts := 0
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		IsRetryable: func(err error) bool { return errors.Is(err, errRetryable) },
+	}
+
+	err = WithTxRetry(context.Background(), NewForTx(db), policy, func(ctx context.Context, tx *sql.Tx) error {
+		attempts++
+		if attempts == 1 {
+			return errRetryable
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxRetry: unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWithTxRetry_ExhaustsAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+	}
+
+	policy := RetryPolicy{
+		MaxAttempts: 2,
+		BaseBackoff: time.Millisecond,
+		IsRetryable: func(err error) bool { return errors.Is(err, errRetryable) },
+	}
+
+	err = WithTxRetry(context.Background(), NewForTx(db), policy, func(ctx context.Context, tx *sql.Tx) error {
+		return errRetryable
+	})
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got %v (%T)", err, err)
+	}
+	if retryErr.Attempts != 2 {
+		t.Fatalf("expected Attempts=2, got %d", retryErr.Attempts)
+	}
+	if !errors.Is(retryErr, errRetryable) {
+		t.Fatalf("expected RetryError to wrap errRetryable")
+	}
+}
+
+func TestWithTxRetry_RejectsNestedTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	policy := RetryPolicy{MaxAttempts: 3, IsRetryable: func(error) bool { return true }}
+
+	outerErr := WithTxContext(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		return WithTxRetry(ctx, NewForTx(db), policy, func(ctx context.Context, tx *sql.Tx) error {
+			return nil
+		})
+	})
+	if !errors.Is(outerErr, ErrNestedRetry) {
+		t.Fatalf("expected ErrNestedRetry, got %v", outerErr)
+	}
+}
@@ -0,0 +1,33 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Tracer menerima event siklus hidup transaksi sehingga operator bisa
+// memantau hot spot dan retry storm dari luar tanpa membungkus *sql.DB
+// sendiri. StartTx dipanggil sebelum BeginTx dan boleh mengembalikan ctx
+// baru (mis. ctx yang sudah membawa span), EndTx dipanggil sekali di akhir
+// dengan err nil untuk commit sukses, dan OnRetry dipanggil oleh WithTxRetry
+// setiap kali sebuah percobaan gagal dan akan diulang.
+type Tracer interface {
+	StartTx(ctx context.Context, opts sql.TxOptions) context.Context
+	EndTx(ctx context.Context, err error)
+	OnRetry(ctx context.Context, attempt int, err error)
+}
+
+// Logger mencatat transaksi yang berjalan lebih lama dari threshold yang
+// dikonfigurasi lewat WithLogger.
+type Logger interface {
+	SlowTransaction(ctx context.Context, d time.Duration)
+}
+
+// noopTracer adalah Tracer default ketika pemanggil tidak mengonfigurasi
+// WithTracer, supaya kode pemanggilan tidak perlu nil-check di mana-mana.
+type noopTracer struct{}
+
+func (noopTracer) StartTx(ctx context.Context, _ sql.TxOptions) context.Context { return ctx }
+func (noopTracer) EndTx(context.Context, error)                                 {}
+func (noopTracer) OnRetry(context.Context, int, error)                          {}
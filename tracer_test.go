@@ -0,0 +1,166 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type fakeTracer struct {
+	startCalls int
+	endErrs    []error
+	retryErrs  []error
+}
+
+func (f *fakeTracer) StartTx(ctx context.Context, _ sql.TxOptions) context.Context {
+	f.startCalls++
+	return ctx
+}
+
+func (f *fakeTracer) EndTx(_ context.Context, err error) {
+	f.endErrs = append(f.endErrs, err)
+}
+
+func (f *fakeTracer) OnRetry(_ context.Context, _ int, err error) {
+	f.retryErrs = append(f.retryErrs, err)
+}
+
+func TestWithTxContextOpts_TracerSeesCommitAndRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	tracer := &fakeTracer{}
+
+	err = WithTxContextOpts(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		return nil
+	}, WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = WithTxContextOpts(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		return errRetryable
+	}, WithTracer(tracer))
+	if !errors.Is(err, errRetryable) {
+		t.Fatalf("expected errRetryable, got %v", err)
+	}
+
+	if tracer.startCalls != 2 {
+		t.Fatalf("expected StartTx called twice, got %d", tracer.startCalls)
+	}
+	if len(tracer.endErrs) != 2 {
+		t.Fatalf("expected EndTx called twice, got %d", len(tracer.endErrs))
+	}
+	if tracer.endErrs[0] != nil {
+		t.Fatalf("expected EndTx(nil) for the committed attempt, got %v", tracer.endErrs[0])
+	}
+	if !errors.Is(tracer.endErrs[1], errRetryable) {
+		t.Fatalf("expected EndTx to report the rollback cause, got %v", tracer.endErrs[1])
+	}
+}
+
+func TestWithTxContextOpts_IgnoredErrorCommitsAndTracerSeesSuccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	tracer := &fakeTracer{}
+	sentinel := errors.New("not found")
+
+	err = WithTxContextOpts(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		return sentinel
+	}, WithTracer(tracer), WithIgnoredErrors(sentinel))
+
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("expected the sentinel error back verbatim, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected tx.Commit() to run despite the ignored error: %v", err)
+	}
+	if len(tracer.endErrs) != 1 || tracer.endErrs[0] != nil {
+		t.Fatalf("expected EndTx(nil) since the tx actually committed, got %v", tracer.endErrs)
+	}
+}
+
+func TestWithTxRetry_TracerSeesOnRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	tracer := &fakeTracer{}
+	attempts := 0
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		IsRetryable: func(err error) bool { return errors.Is(err, errRetryable) },
+		Tracer:      tracer,
+	}
+
+	err = WithTxRetry(context.Background(), NewForTx(db), policy, func(ctx context.Context, tx *sql.Tx) error {
+		attempts++
+		if attempts == 1 {
+			return errRetryable
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tracer.retryErrs) != 1 || !errors.Is(tracer.retryErrs[0], errRetryable) {
+		t.Fatalf("expected OnRetry called once with errRetryable, got %v", tracer.retryErrs)
+	}
+}
+
+type fakeLogger struct {
+	slowCalls int
+}
+
+func (f *fakeLogger) SlowTransaction(context.Context, time.Duration) {
+	f.slowCalls++
+}
+
+func TestWithTxContextOpts_LoggerFiresAboveThreshold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	logger := &fakeLogger{}
+	err = WithTxContextOpts(context.Background(), NewForTx(db), func(ctx context.Context, tx *sql.Tx) error {
+		return nil
+	}, WithLogger(logger, time.Nanosecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logger.slowCalls != 1 {
+		t.Fatalf("expected SlowTransaction called once, got %d", logger.slowCalls)
+	}
+}